@@ -0,0 +1,168 @@
+// Copyright 2021 FerretDB Inc.
+// Copyright 2026 Alexey Palazhchenko.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// groupMember is one resource tracked in a [HandleGroup].
+type groupMember struct {
+	h       *Handle
+	release func()
+}
+
+// groupState holds a [HandleGroup]'s members and creation stack.
+//
+// It is a separate allocation from [HandleGroup] itself, so that it can be passed
+// to [runtime.AddCleanup] without keeping the group reachable.
+type groupState struct {
+	m       sync.Mutex
+	members []*groupMember
+	pcs     []uintptr
+	closed  bool
+}
+
+// HandleGroup lets a parent resource track a set of child resources it owns,
+// and release them atomically, in LIFO order, with a single [HandleGroup.Close] call.
+//
+// It must be created with [NewGroup].
+// Children are registered with [TrackIn] instead of [Track].
+//
+// It is recommended to store it as non-embedded pointer field of a resource struct being tracked,
+// just like [Handle].
+type HandleGroup struct {
+	state *groupState
+	c     atomic.Pointer[runtime.Cleanup]
+}
+
+// NewGroup creates a new [HandleGroup].
+//
+// If the group becomes unreachable and is garbage-collected before [HandleGroup.Close] is called,
+// every resource still tracked in it is reported as leaked, one [LeakEvent] per resource,
+// using the stack captured by this call.
+func NewGroup() *HandleGroup {
+	state := new(groupState)
+
+	if collectStack {
+		stk := make([]uintptr, 32)
+		n := runtime.Callers(2, stk[:])
+		state.pcs = stk[:n]
+	}
+
+	g := &HandleGroup{state: state}
+
+	c := runtime.AddCleanup(g, groupCleanup, state)
+	g.c.Store(&c)
+
+	return g
+}
+
+// GroupHandle is the handle for a resource tracked with [TrackIn].
+//
+// Unlike a [Handle], it must never be passed to [Untrack]: only the [HandleGroup]
+// it was tracked in may release it, which happens as part of [HandleGroup.Close].
+// Its distinct type makes that misuse a compile error instead of a silent no-op.
+type GroupHandle struct {
+	h *Handle
+}
+
+// TrackIn tracks the lifetime of resource like [Track], and registers it with g
+// so that [HandleGroup.Close] releases it together with the group's other members.
+//
+// Unlike a resource tracked with [Track], resource itself is not individually enforced
+// by the runtime; if it leaks, that is reported when g itself is detected as leaked.
+//
+// If g is already closed, there would be nothing left to release resource, so instead of
+// silently orphaning it, TrackIn immediately releases it and reports it as leaked.
+// Unlike a real group leak, which leaves the pprof entry in place and attributes the event
+// to g's creation stack, this path removes the pprof entry immediately and attributes
+// the event to resource's own TrackIn call.
+func TrackIn[T any](resource *T, g *HandleGroup) *GroupHandle {
+	if g == nil {
+		panic("group must not be nil")
+	}
+
+	h := NewHandle()
+	prepare(resource, h)
+
+	g.state.m.Lock()
+	closed := g.state.closed
+	if !closed {
+		g.state.members = append(g.state.members, &groupMember{
+			h: h,
+			release: func() {
+				release(h)
+				runtime.KeepAlive(resource)
+			},
+		})
+	}
+	g.state.m.Unlock()
+
+	if closed {
+		e := h.leakEvent()
+		leakStats(e.Type)
+		releasePprof(h)
+		dispatch(e)
+		runtime.KeepAlive(resource)
+	}
+
+	return &GroupHandle{h: h}
+}
+
+// Close releases every resource tracked in g, in LIFO order, and stops tracking g itself.
+//
+// It is safe to call Close multiple times; only the first call releases members.
+func (g *HandleGroup) Close() {
+	if c := g.c.Swap(nil); c != nil {
+		c.Stop()
+	}
+
+	// ensure that g is still reachable before we have a chance to cancel the cleanup call
+	runtime.KeepAlive(g)
+
+	g.state.m.Lock()
+	members := g.state.members
+	g.state.members = nil
+	g.state.closed = true
+	g.state.m.Unlock()
+
+	for i := len(members) - 1; i >= 0; i-- {
+		members[i].release()
+	}
+}
+
+// groupCleanup is called by the runtime when a [HandleGroup] becomes unreachable
+// without [HandleGroup.Close] being called.
+// It reports every resource still tracked in state as leaked, in LIFO order,
+// attributing all of them to the stack captured by [NewGroup].
+func groupCleanup(state *groupState) {
+	state.m.Lock()
+	members := state.members
+	state.members = nil
+	state.m.Unlock()
+
+	for i := len(members) - 1; i >= 0; i-- {
+		e := members[i].h.leakEvent()
+		e.PCs = state.pcs
+		e.Frames = framesFor(state.pcs)
+
+		leakStats(e.Type)
+		dispatch(e)
+	}
+}
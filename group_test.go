@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+// Copyright 2026 Alexey Palazhchenko.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"runtime"
+	"runtime/pprof"
+	"testing"
+)
+
+// GroupChild represents a child resource owned by a [HandleGroup] for tests.
+type GroupChild struct {
+	h *Handle
+}
+
+func TestGroupClose(t *testing.T) {
+	SetLeakHandler(panicLeakHandler)
+
+	profile := "resource/resource.GroupChild"
+
+	g := NewGroup()
+
+	a := &GroupChild{}
+	b := &GroupChild{}
+
+	TrackIn(a, g)
+	TrackIn(b, g)
+
+	assertEqual(t, 2, pprof.Lookup(profile).Count())
+
+	g.Close()
+
+	assertEqual(t, 0, pprof.Lookup(profile).Count())
+
+	// Close is safe to call again
+	g.Close()
+}
+
+func TestGroupLeak(t *testing.T) {
+	profile := "resource/resource.GroupChild"
+
+	ch := make(chan *LeakEvent, 2)
+
+	t.Cleanup(func() { SetLeakHandlerFor[GroupChild](nil) })
+	SetLeakHandlerFor[GroupChild](func(e *LeakEvent) {
+		ch <- e
+	})
+
+	var ha, hb *GroupHandle
+
+	func() {
+		g := NewGroup()
+
+		a := &GroupChild{}
+		b := &GroupChild{}
+
+		ha = TrackIn(a, g)
+		hb = TrackIn(b, g)
+
+		assertEqual(t, 2, pprof.Lookup(profile).Count())
+
+		// g (and a, b) become unreachable here without Close being called
+	}()
+
+	runtime.GC()
+
+	e1 := <-ch
+	e2 := <-ch
+
+	assertEqual(t, "*resource.GroupChild", e1.Type)
+	assertEqual(t, "*resource.GroupChild", e2.Type)
+
+	// remove profile manually to support `go test -count=X`
+	pprof.Lookup(profile).Remove(ha.h)
+	pprof.Lookup(profile).Remove(hb.h)
+	assertEqual(t, 0, pprof.Lookup(profile).Count())
+}
+
+func TestGroupTrackInClosed(t *testing.T) {
+	profile := "resource/resource.GroupChild"
+
+	ch := make(chan *LeakEvent, 1)
+
+	t.Cleanup(func() { SetLeakHandlerFor[GroupChild](nil) })
+	SetLeakHandlerFor[GroupChild](func(e *LeakEvent) {
+		ch <- e
+	})
+
+	g := NewGroup()
+	g.Close()
+
+	c := &GroupChild{}
+	TrackIn(c, g)
+
+	e := <-ch
+	assertEqual(t, "*resource.GroupChild", e.Type)
+
+	// TrackIn released c immediately, so the profile must already be empty
+	assertEqual(t, 0, pprof.Lookup(profile).Count())
+
+	// Close is safe to call again, and must not release c a second time
+	g.Close()
+}
@@ -16,9 +16,9 @@
 package resource
 
 import (
-	"fmt"
 	"runtime"
 	"sync/atomic"
+	"time"
 )
 
 // Handle holds the [runtime.Cleanup] to stop resource lifetime tracking.
@@ -42,22 +42,35 @@ func NewHandle() *Handle {
 	return new(Handle)
 }
 
-// buildPanicMsg builds a panic message.
-func (h *Handle) buildPanicMsg() string {
-	msg := h.typ + " became unreachable without being released!"
-	if h.pcs != nil {
-		msg += "\nIt started being tracked at:\n"
+// leakEvent builds the [LeakEvent] reported when h's resource is no longer reachable.
+func (h *Handle) leakEvent() *LeakEvent {
+	return &LeakEvent{
+		Type:    h.typ,
+		Profile: h.profile,
+		PCs:     h.pcs,
+		Frames:  framesFor(h.pcs),
+		Time:    time.Now(),
+	}
+}
+
+// framesFor resolves pcs into call frames, innermost frame first.
+// It returns nil if pcs is nil.
+func framesFor(pcs []uintptr) []runtime.Frame {
+	if pcs == nil {
+		return nil
+	}
+
+	var frames []runtime.Frame
 
-		frames := runtime.CallersFrames(h.pcs)
-		for {
-			frame, more := frames.Next()
-			msg += fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	fs := runtime.CallersFrames(pcs)
+	for {
+		frame, more := fs.Next()
+		frames = append(frames, frame)
 
-			if !more {
-				break
-			}
+		if !more {
+			break
 		}
 	}
 
-	return msg
+	return frames
 }
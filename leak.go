@@ -0,0 +1,138 @@
+// Copyright 2021 FerretDB Inc.
+// Copyright 2026 Alexey Palazhchenko.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LeakEvent describes a tracked resource that became unreachable without being released.
+type LeakEvent struct {
+	// Type is the tracked resource's type, as reported by [reflect.Type.String].
+	Type string
+
+	// PCs are the program counters captured by [Track] or [TrackIn], innermost frame first.
+	// For a resource leaked together with its [HandleGroup], they are those captured by [NewGroup].
+	PCs []uintptr
+
+	// Frames are the call frames resolved from PCs, innermost frame first.
+	Frames []runtime.Frame
+
+	// Profile is the name of the pprof profile the resource was registered under, if any.
+	Profile string
+
+	// Time is the moment the leak was detected.
+	Time time.Time
+}
+
+// message builds the default human-readable description of the leak,
+// used by the default handler and mirrored in tests.
+func (e *LeakEvent) message() string {
+	msg := e.Type + " became unreachable without being released!"
+	if len(e.Frames) > 0 {
+		msg += "\nIt started being tracked at:\n"
+
+		for _, frame := range e.Frames {
+			msg += fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+	}
+
+	return msg
+}
+
+// LeakHandler is called with a [LeakEvent] when a tracked resource leaks.
+//
+// Handlers are invoked from a cleanup goroutine started by the runtime,
+// except when [TrackIn] is called with an already closed group: then the handler runs
+// in the goroutine calling TrackIn. Handlers must not panic unless that is the intended way to report the leak,
+// and they should return quickly.
+type LeakHandler func(*LeakEvent)
+
+// panicLeakHandler is the default [LeakHandler]. It panics with a message describing the leak.
+func panicLeakHandler(e *LeakEvent) {
+	panic(e.message())
+}
+
+// handler is the current global [LeakHandler], used for types without a more specific one
+// set with [SetLeakHandlerFor].
+var handler atomic.Pointer[LeakHandler]
+
+func init() {
+	var h LeakHandler = panicLeakHandler
+	handler.Store(&h)
+}
+
+// typeHandlersM protects typeHandlers.
+var typeHandlersM sync.RWMutex
+
+// typeHandlers maps a resource pointer type string (as produced by [reflect.Type.String],
+// e.g. "*pkg.Type") to its [LeakHandler].
+var typeHandlers = map[string]LeakHandler{}
+
+// SetLeakHandler replaces the global handler invoked when a tracked resource leaks.
+//
+// The default handler panics with a message describing the leak and the stack trace
+// captured when tracking started; most callers instead route leaks to their logger,
+// to metrics, or to [runtime/trace].
+//
+// It does not affect types that have a handler set with [SetLeakHandlerFor].
+//
+// It is safe to call SetLeakHandler concurrently with itself, [SetLeakHandlerFor], [Track], and [Untrack].
+func SetLeakHandler(h LeakHandler) {
+	if h == nil {
+		panic("handler must not be nil")
+	}
+
+	handler.Store(&h)
+}
+
+// SetLeakHandlerFor replaces the handler invoked when a tracked resource of type T leaks,
+// overriding the global handler set with [SetLeakHandler] for that type only.
+// Passing a nil handler removes the override, falling back to the global handler again.
+//
+// It is safe to call SetLeakHandlerFor concurrently with itself, [SetLeakHandler], [Track], and [Untrack].
+func SetLeakHandlerFor[T any](h LeakHandler) {
+	// Matches the type string [Track] records in Handle.typ, i.e. including the pointer.
+	typ := reflect.TypeOf((*T)(nil)).String()
+
+	typeHandlersM.Lock()
+	defer typeHandlersM.Unlock()
+
+	if h == nil {
+		delete(typeHandlers, typ)
+		return
+	}
+
+	typeHandlers[typ] = h
+}
+
+// dispatch calls the handler registered for event's type, falling back to the global handler.
+func dispatch(e *LeakEvent) {
+	typeHandlersM.RLock()
+	h, ok := typeHandlers[e.Type]
+	typeHandlersM.RUnlock()
+
+	if !ok {
+		h = *handler.Load()
+	}
+
+	h(e)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 FerretDB Inc.
+// Copyright 2026 Alexey Palazhchenko.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"testing"
+)
+
+// OtherResource represents a second tracked resource type for tests.
+type OtherResource struct {
+	h *Handle
+}
+
+func TestSetLeakHandlerFor(t *testing.T) {
+	SetLeakHandler(panicLeakHandler)
+
+	profile := "resource/resource.OtherResource"
+
+	global := make(chan *LeakEvent, 1)
+	perType := make(chan *LeakEvent, 1)
+
+	t.Cleanup(func() {
+		SetLeakHandler(panicLeakHandler)
+		SetLeakHandlerFor[OtherResource](nil)
+	})
+
+	SetLeakHandler(func(e *LeakEvent) {
+		global <- e
+	})
+
+	h := NewHandle()
+	res := &OtherResource{h: h}
+	Track(res, h)
+
+	runtime.GC()
+
+	select {
+	case e := <-global:
+		assertEqual(t, "*resource.OtherResource", e.Type)
+	case <-perType:
+		t.Fatal("unexpected per-type handler call before it was set")
+	}
+
+	pprof.Lookup(profile).Remove(h)
+
+	SetLeakHandlerFor[OtherResource](func(e *LeakEvent) {
+		perType <- e
+	})
+
+	h = NewHandle()
+	res = &OtherResource{h: h}
+	Track(res, h)
+
+	runtime.GC()
+
+	select {
+	case <-global:
+		t.Fatal("global handler should not be called once a per-type handler is set")
+	case e := <-perType:
+		assertEqual(t, "*resource.OtherResource", e.Type)
+	}
+
+	pprof.Lookup(profile).Remove(h)
+}
+
+func TestSetLeakHandlerConcurrently(t *testing.T) {
+	SetLeakHandler(panicLeakHandler)
+	t.Cleanup(func() { SetLeakHandler(panicLeakHandler) })
+
+	var wg sync.WaitGroup
+
+	for i := range runtime.GOMAXPROCS(-1) * 10 {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if i%2 == 0 {
+				SetLeakHandler(func(*LeakEvent) {})
+				return
+			}
+
+			SetLeakHandlerFor[OtherResource](func(*LeakEvent) {})
+		}(i)
+	}
+
+	wg.Wait()
+
+	SetLeakHandlerFor[OtherResource](nil)
+}
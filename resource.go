@@ -27,9 +27,12 @@
 //
 // Resource's Close method implementation should call [Untrack].
 // If the resource becomes unreachable and is garbage-collected without this method being called,
-// the runtime would panic with a stack trace showing the Track call.
+// the leak is reported to a [LeakHandler]; by default, it panics with a stack trace showing the Track call.
+// Applications can install their own handler with [SetLeakHandler] or, for a single resource type,
+// with [SetLeakHandlerFor] to route leaks to a logger, to metrics, or to [runtime/trace] instead.
 //
 // Additionally, currently traced resources are shown in custom pprof profiles named after resource types.
+// Live counts and allocation/release rates are available through [Stats], [StatsFor], and [StatsHandler].
 //
 // [custom pprof profiles]: https://tip.golang.org/wiki/CustomPprofProfiles
 package resource
@@ -49,19 +52,21 @@ const (
 
 // cleanup is called by the runtime when the [Track]ed resource is no longer reachable,
 // but [Untrack] wasn't called on it.
-// It panics with the given message.
-//
-// This variable is overridden in tests.
-var cleanup = func(h *Handle) {
-	msg := h.buildPanicMsg()
-	panic(msg)
+// It reports the leak through the handler set by [SetLeakHandler] or [SetLeakHandlerFor].
+func cleanup(h *Handle) {
+	e := h.leakEvent()
+	leakStats(e.Type)
+	dispatch(e)
 }
 
 // pprofM protects access to pprof profiles.
 var pprofM sync.Mutex
 
-// Track tracks the lifetime of an resource until [Untrack] is called on it.
-func Track[T any](resource *T, h *Handle) {
+// prepare registers resource's pprof profile membership on h, and captures its type and stack on it.
+//
+// It is shared by [Track], which additionally arranges for [Untrack] to be enforced by the runtime,
+// and [TrackIn], which instead relies on the owning [HandleGroup] for that.
+func prepare[T any](resource *T, h *Handle) {
 	if resource == nil {
 		panic("resource must not be nil")
 	}
@@ -98,20 +103,48 @@ func Track[T any](resource *T, h *Handle) {
 	}
 
 	h.typ = reflect.TypeOf(resource).String()
+	trackStats(h.typ)
 
 	if collectStack {
 		// It would be nice to access pprof.Profile's PCs.
 		// Unfortunately, the only way to get them is through p.WriteTo,
 		// and parsing text or protobuf would be overkill.
 		stk := make([]uintptr, 32)
-		n := runtime.Callers(2, stk[:])
+		n := runtime.Callers(3, stk[:])
 		h.pcs = stk[:n]
 	}
+}
+
+// Track tracks the lifetime of an resource until [Untrack] is called on it.
+func Track[T any](resource *T, h *Handle) {
+	prepare(resource, h)
 
 	c := runtime.AddCleanup(resource, cleanup, h)
 	h.c.Store(&c)
 }
 
+// releasePprof removes h's resource from its pprof profile.
+//
+// The caller must ensure that it is called at most once per tracked resource.
+func releasePprof(h *Handle) {
+	if pprofEnabled {
+		p := pprof.Lookup(h.profile)
+		if p == nil {
+			panic("resource is not tracked")
+		}
+
+		p.Remove(h)
+	}
+}
+
+// release removes h's resource from its pprof profile and updates [Stats].
+//
+// The caller must ensure that it is called at most once per tracked resource.
+func release(h *Handle) {
+	untrackStats(h.typ)
+	releasePprof(h)
+}
+
 // Untrack stops tracking the lifetime of an resource.
 //
 // It is safe to call this function multiple times concurrently.
@@ -124,21 +157,13 @@ func Untrack[T any](resource *T, h *Handle) {
 		panic("handle must not be nil")
 	}
 
-	if h := h.c.Swap(nil); h != nil {
-		h.Stop()
+	if c := h.c.Swap(nil); c != nil {
+		c.Stop()
+		release(h)
 	}
 
 	// ensure that resource is still reachable before we have a chance to cancel the cleanup call
 	runtime.KeepAlive(resource)
-
-	if pprofEnabled {
-		p := pprof.Lookup(h.profile)
-		if p == nil {
-			panic("resource is not tracked")
-		}
-
-		p.Remove(h)
-	}
 }
 
 // profileName return pprof profile name for the given pointer.
@@ -26,8 +26,6 @@ import (
 	"testing"
 )
 
-var origCleanup = cleanup
-
 // assertEqual fails the test if expected and actual are not equal.
 func assertEqual[T any](t testing.TB, expected, actual T) {
 	t.Helper()
@@ -50,7 +48,7 @@ var globalResource *Resource
 // See https://go.dev/doc/gc-guide#Testing_object_death
 // and https://pkg.go.dev/cmd/compile#hdr-Line_Directives.
 func TestTrackUntrack(t *testing.T) {
-	cleanup = origCleanup
+	SetLeakHandler(panicLeakHandler)
 
 	profile := "resource/resource.Resource"
 
@@ -80,10 +78,10 @@ func TestTrackUntrack(t *testing.T) {
 		h := NewHandle()
 		ch := make(chan string, 1)
 
-		t.Cleanup(func() { cleanup = origCleanup })
-		cleanup = func(h *Handle) {
-			ch <- h.buildPanicMsg()
-		}
+		t.Cleanup(func() { SetLeakHandler(panicLeakHandler) })
+		SetLeakHandler(func(e *LeakEvent) {
+			ch <- e.message()
+		})
 
 		res := &Resource{h: h}
 
@@ -126,7 +124,7 @@ func TestTrackUntrack(t *testing.T) {
 }
 
 func TestUntrackConcurrently(t *testing.T) {
-	cleanup = origCleanup
+	SetLeakHandler(panicLeakHandler)
 
 	res := &Resource{h: NewHandle()}
 	Track(res, res.h)
@@ -169,7 +167,7 @@ func TestUntrackConcurrently(t *testing.T) {
 }
 
 func TestStacks(t *testing.T) {
-	cleanup = origCleanup
+	SetLeakHandler(panicLeakHandler)
 
 	profile := "resource/resource.Resource"
 
@@ -177,15 +175,15 @@ func TestStacks(t *testing.T) {
 	ch := make(chan string, 1)
 
 	t.Cleanup(func() {
-		cleanup = origCleanup
+		SetLeakHandler(panicLeakHandler)
 
 		// remove profile manually to support `go test -count=X`
 		pprof.Lookup(profile).Remove(h)
 		assertEqual(t, 0, pprof.Lookup(profile).Count())
 	})
-	cleanup = func(h *Handle) {
-		ch <- h.buildPanicMsg()
-	}
+	SetLeakHandler(func(e *LeakEvent) {
+		ch <- e.message()
+	})
 
 	res := &Resource{h: h}
 
@@ -196,7 +194,7 @@ func TestStacks(t *testing.T) {
 	msg := <-ch
 	t.Logf("stack:\n%s", msg)
 
-	// resource.Resource became unreachable without being released!
+	// *resource.Resource became unreachable without being released!
 	// It started being tracked at:
 	// github.com/AlekSi/resource.TestStacks
 	// 	testtrack.go:400
@@ -205,7 +203,7 @@ func TestStacks(t *testing.T) {
 	// runtime.goexit
 	// 	/opt/homebrew/Cellar/go/1.25.7_1/libexec/src/runtime/asm_arm64.s:1268
 	expected := []*regexp.Regexp{
-		0: regexp.MustCompile(`^\Qresource.Resource became unreachable without being released!\E$`),
+		0: regexp.MustCompile(`^\Q*resource.Resource became unreachable without being released!\E$`),
 		1: regexp.MustCompile(`^\QIt started being tracked at:\E$`),
 		2: regexp.MustCompile(`^\Qgithub.com/AlekSi/resource.TestStacks\E$`),
 		3: regexp.MustCompile(`^\ttesttrack\.go:400$`),
@@ -251,10 +249,10 @@ func TestStacks(t *testing.T) {
 }
 
 func Example() {
-	// The default cleanup function panics with a stack trace of the Track call.
-	cleanup = func(h *Handle) {
-		fmt.Printf("%s wasn't released!", h.typ)
-	}
+	// The default handler panics with a stack trace of the Track call.
+	SetLeakHandler(func(e *LeakEvent) {
+		fmt.Printf("%s wasn't released!", e.Type)
+	})
 
 	res := &Resource{
 		h: NewHandle(),
@@ -266,5 +264,5 @@ func Example() {
 	runtime.GC()
 
 	// Output:
-	// resource.Resource wasn't released!
+	// *resource.Resource wasn't released!
 }
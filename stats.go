@@ -0,0 +1,226 @@
+// Copyright 2021 FerretDB Inc.
+// Copyright 2026 Alexey Palazhchenko.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"expvar"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsTau is the time constant of the exponential moving average used for [TypeStats.Rate].
+const statsTau = 5 * time.Second
+
+// TypeStats holds observability counters for a single tracked resource type.
+type TypeStats struct {
+	// Live is the number of currently tracked resources of this type.
+	Live int64
+
+	// TotalAllocated is the total number of resources of this type that were ever tracked.
+	TotalAllocated int64
+
+	// TotalReleased is the total number of resources of this type released through [Untrack]
+	// or [HandleGroup.Close].
+	TotalReleased int64
+
+	// TotalLeaked is the total number of resources of this type reported as leaked.
+	TotalLeaked int64
+
+	// Rate is the exponential moving average of track, release, and leak events per second for this type,
+	// sampled with a time constant of statsTau.
+	Rate float64
+}
+
+// typeStats holds the mutable counters and EMA sampling state backing [TypeStats] for one resource type.
+type typeStats struct {
+	m sync.Mutex
+
+	live           int64
+	totalAllocated int64
+	totalReleased  int64
+	totalLeaked    int64
+
+	lastSample time.Time
+	ema        float64
+}
+
+// sample updates s.ema for an event observed at now.
+//
+// The caller must hold s.m.
+func (s *typeStats) sample(now time.Time) {
+	if s.lastSample.IsZero() {
+		s.lastSample = now
+		return
+	}
+
+	dt := now.Sub(s.lastSample).Seconds()
+	if dt <= 0 {
+		return
+	}
+
+	decay := math.Exp(-dt / statsTau.Seconds())
+	s.ema = s.ema*decay + (1/dt)*(1-decay)
+	s.lastSample = now
+}
+
+// snapshot returns the current [TypeStats] for s.
+func (s *typeStats) snapshot() TypeStats {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return TypeStats{
+		Live:           s.live,
+		TotalAllocated: s.totalAllocated,
+		TotalReleased:  s.totalReleased,
+		TotalLeaked:    s.totalLeaked,
+		Rate:           s.ema,
+	}
+}
+
+// statsM protects typesStats.
+var statsM sync.RWMutex
+
+// typesStats maps a resource type string to its counters.
+var typesStats = map[string]*typeStats{}
+
+// statsFor returns the typeStats for typ, creating it on first use.
+func statsFor(typ string) *typeStats {
+	statsM.RLock()
+	s, ok := typesStats[typ]
+	statsM.RUnlock()
+
+	if ok {
+		return s
+	}
+
+	statsM.Lock()
+	defer statsM.Unlock()
+
+	if s, ok = typesStats[typ]; ok {
+		return s
+	}
+
+	s = new(typeStats)
+	typesStats[typ] = s
+
+	return s
+}
+
+// trackStats records that a resource of type typ started being tracked.
+func trackStats(typ string) {
+	s := statsFor(typ)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.live++
+	s.totalAllocated++
+	s.sample(time.Now())
+}
+
+// untrackStats records that a resource of type typ was released through [Untrack] or [HandleGroup.Close].
+func untrackStats(typ string) {
+	s := statsFor(typ)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.live--
+	s.totalReleased++
+	s.sample(time.Now())
+}
+
+// leakStats records that a resource of type typ was reported as leaked.
+func leakStats(typ string) {
+	s := statsFor(typ)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.live--
+	s.totalLeaked++
+	s.sample(time.Now())
+}
+
+// Stats returns a snapshot of observability counters for every resource type tracked so far.
+func Stats() map[string]TypeStats {
+	statsM.RLock()
+	defer statsM.RUnlock()
+
+	res := make(map[string]TypeStats, len(typesStats))
+	for typ, s := range typesStats {
+		res[typ] = s.snapshot()
+	}
+
+	return res
+}
+
+// StatsFor returns a snapshot of observability counters for resource type T.
+//
+// It returns a zero [TypeStats] if no resource of that type has been tracked yet.
+func StatsFor[T any]() TypeStats {
+	// Matches the type string [Track] records in Handle.typ, i.e. including the pointer.
+	typ := reflect.TypeOf((*T)(nil)).String()
+
+	statsM.RLock()
+	s, ok := typesStats[typ]
+	statsM.RUnlock()
+
+	if !ok {
+		return TypeStats{}
+	}
+
+	return s.snapshot()
+}
+
+// statsVar publishes [Stats] under the "resource" expvar name.
+var statsVar = expvar.Func(func() any { return Stats() })
+
+func init() {
+	expvar.Publish("resource", statsVar)
+}
+
+// StatsHandler returns an [http.Handler] that renders a table of the current [Stats] as HTML.
+func StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		all := Stats()
+
+		types := make([]string, 0, len(all))
+		for typ := range all {
+			types = append(types, typ)
+		}
+
+		sort.Strings(types)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		fmt.Fprint(w, "<table>\n<tr><th>Type</th><th>Live</th><th>Allocated</th><th>Released</th>"+
+			"<th>Leaked</th><th>Rate/s</th></tr>\n")
+
+		for _, typ := range types {
+			s := all[typ]
+			fmt.Fprintf(w, "<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%.3f</td></tr>\n",
+				typ, s.Live, s.TotalAllocated, s.TotalReleased, s.TotalLeaked, s.Rate)
+		}
+
+		fmt.Fprint(w, "</table>\n")
+	})
+}
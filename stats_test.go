@@ -0,0 +1,72 @@
+// Copyright 2021 FerretDB Inc.
+// Copyright 2026 Alexey Palazhchenko.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// StatsResource represents a tracked resource type dedicated to stats tests,
+// so that counters from other tests do not leak into assertions here.
+type StatsResource struct {
+	h *Handle
+}
+
+func TestStats(t *testing.T) {
+	typ := "*resource.StatsResource"
+
+	// typesStats is package-level state, so assert deltas rather than absolute
+	// values to remain repeatable under `go test -count=X`.
+	before := StatsFor[StatsResource]()
+
+	res := &StatsResource{h: NewHandle()}
+	Track(res, res.h)
+
+	s := StatsFor[StatsResource]()
+	assertEqual(t, before.Live+1, s.Live)
+	assertEqual(t, before.TotalAllocated+1, s.TotalAllocated)
+	assertEqual(t, before.TotalReleased, s.TotalReleased)
+	assertEqual(t, before.TotalLeaked, s.TotalLeaked)
+
+	Untrack(res, res.h)
+
+	s = StatsFor[StatsResource]()
+	assertEqual(t, before.Live, s.Live)
+	assertEqual(t, before.TotalAllocated+1, s.TotalAllocated)
+	assertEqual(t, before.TotalReleased+1, s.TotalReleased)
+	assertEqual(t, before.TotalLeaked, s.TotalLeaked)
+
+	all := Stats()
+	assertEqual(t, s, all[typ])
+}
+
+func TestStatsHandler(t *testing.T) {
+	res := &StatsResource{h: NewHandle()}
+	Track(res, res.h)
+	t.Cleanup(func() { Untrack(res, res.h) })
+
+	req := httptest.NewRequest("GET", "/debug/resource", nil)
+	rec := httptest.NewRecorder()
+
+	StatsHandler().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+
+	assertEqual(t, true, strings.Contains(body, "<table>"))
+	assertEqual(t, true, strings.Contains(body, "*resource.StatsResource"))
+}